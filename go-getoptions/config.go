@@ -0,0 +1,111 @@
+package getoptions
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ConfigFormat selects the file format understood by LoadConfig and
+// LoadConfigReader.
+type ConfigFormat int
+
+const (
+	// ConfigINI parses `key = value` pairs grouped under `[section]`
+	// headers, where a dotted section name (`cmd1.sub1cmd1`) addresses a
+	// nested command the same way a CLI invocation would.
+	ConfigINI ConfigFormat = iota
+	// ConfigTOML parses the file as TOML; tables map to command nesting
+	// the same way ConfigINI sections do. There is no TOML library
+	// vendored for this: decodeTOML hand-scans `[table]`/`key = value`
+	// lines only and errors out on arrays, inline tables, arrays-of-tables
+	// and multi-line strings rather than misreading them; see its doc
+	// comment for what it does not detect.
+	ConfigTOML
+	// ConfigYAML parses the file as YAML; nested maps map to command
+	// nesting the same way ConfigINI sections do. There is no YAML
+	// library vendored for this: decodeYAML hand-scans indented
+	// `key: value` lines only and errors out on lists, flow style and
+	// anchors/aliases rather than misreading them; see its doc comment for
+	// what it does not detect.
+	ConfigYAML
+)
+
+// LoadConfig seeds option defaults for gopt's programTree from the file at
+// path, before Parse/parseCLIArgs runs. CLI arguments always override values
+// loaded this way, and values loaded this way override the option's built-in
+// default, so LoadConfig should be called before Parse.
+//
+// Sections (INI) or tables (TOML/YAML) map to command nesting: a section
+// named "cmd1.sub1cmd1" seeds the sub1cmd1 node reachable the same way
+// TestParseCLIArgs reaches it with getNode(tree, "cmd1", "sub1cmd1").
+//
+// Option only keeps a single value, so a key repeated in the file calls
+// Save once per occurrence and the last one wins, same as the CLI repeating
+// --opt twice. The one exception is a struct field bound through
+// NewFromStruct: bindStructField registers an OnSave that appends to a
+// []string field instead of overwriting it, so a repeated key there
+// accumulates every value in file order. There is no equivalent for a map
+// field: NewFromStruct registers it, but every Save on its option fails with
+// "unsupported field kind map".
+func (gopt *GetOpt) LoadConfig(path string, format ConfigFormat) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("loading config %s: %w", path, err)
+	}
+	defer f.Close()
+	return gopt.LoadConfigReader(f, format)
+}
+
+// LoadConfigReader behaves like LoadConfig but reads from an already open
+// r, so tests and callers embedding config data can avoid a temp file.
+func (gopt *GetOpt) LoadConfigReader(r io.Reader, format ConfigFormat) error {
+	entries, err := decodeConfig(r, format)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		n, err := getNode(gopt.programTree, e.path...)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		opt, ok := n.ChildOptions[e.key]
+		if !ok {
+			return fmt.Errorf("loading config: %w", newErrorUnknownOption(e.key, optionNames(n), fmt.Errorf("not found in %s", n.Str())))
+		}
+		if opt.Called {
+			// A CLI invocation already set this option; config values never
+			// override it.
+			continue
+		}
+		if err := opt.Save(e.value); err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		opt.ConfigCalled = true
+	}
+	return nil
+}
+
+// configEntry is one `key = value` pair read from a config file, addressed
+// to the command node given by path (empty for the root node).
+type configEntry struct {
+	path  []string
+	key   string
+	value string
+}
+
+// decodeConfig dispatches to the format-specific decoder. TOML/YAML parsing
+// reuses the same section-path/key/value shape as the INI decoder so
+// LoadConfigReader has a single application loop regardless of format.
+func decodeConfig(r io.Reader, format ConfigFormat) ([]configEntry, error) {
+	switch format {
+	case ConfigINI:
+		return decodeINI(r)
+	case ConfigTOML:
+		return decodeTOML(r)
+	case ConfigYAML:
+		return decodeYAML(r)
+	default:
+		return nil, fmt.Errorf("unknown config format: %d", format)
+	}
+}
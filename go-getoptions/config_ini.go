@@ -0,0 +1,74 @@
+package getoptions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decodeINI parses a minimal INI dialect: `[section.sub]` headers switch the
+// current command path, `key = value` lines (or `key: value`) add an entry,
+// blank lines and lines starting with `;` or `#` are ignored.
+func decodeINI(r io.Reader) ([]configEntry, error) {
+	var entries []configEntry
+	var path []string
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section := strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				path = nil
+			} else {
+				path = strings.Split(section, ".")
+			}
+			continue
+		}
+		key, value, ok := splitKV(line)
+		if !ok {
+			return nil, fmt.Errorf("config line %d: expected 'key = value', got %q", lineNo, line)
+		}
+		entries = append(entries, configEntry{path: append([]string{}, path...), key: key, value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// splitKV splits an INI line on the first '=' or ':', trimming surrounding
+// whitespace and matching quotes from the value.
+func splitKV(line string) (key, value string, ok bool) {
+	idx := strings.IndexAny(line, "=:")
+	if idx < 0 {
+		return "", "", false
+	}
+	key = strings.TrimSpace(line[:idx])
+	value = strings.TrimSpace(line[idx+1:])
+	if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"' || value[0] == '\'' && value[len(value)-1] == '\'') {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, key != ""
+}
+
+// rejectFlowStyleValue errors out on a value decodeTOML/decodeYAML can't
+// actually represent - an array (`[...]`), inline table/flow mapping
+// (`{...}`), or a triple-quoted multi-line string - rather than letting it
+// through as a mangled plain string, since neither decoder is a real
+// parser for its format.
+func rejectFlowStyleValue(lineNo int, value string) error {
+	switch {
+	case strings.HasPrefix(value, "["), strings.HasPrefix(value, "{"):
+		return fmt.Errorf("config line %d: arrays/inline tables are not supported by this hand-rolled decoder, got %q", lineNo, value)
+	case strings.HasPrefix(value, `"""`), strings.HasPrefix(value, "'''"):
+		return fmt.Errorf("config line %d: multi-line strings are not supported by this hand-rolled decoder, got %q", lineNo, value)
+	}
+	return nil
+}
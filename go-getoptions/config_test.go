@@ -0,0 +1,173 @@
+package getoptions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigReaderINI(t *testing.T) {
+	gopt := setupOpt()
+
+	ini := "rootopt1 = hello\n\n[cmd1.sub1cmd1]\nsub1cmd1opt1 = world\n"
+	err := gopt.LoadConfigReader(strings.NewReader(ini), ConfigINI)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opt, ok := gopt.programTree.ChildOptions["rootopt1"]
+	if !ok || !opt.ConfigCalled {
+		t.Fatalf("rootopt1 not seeded from config")
+	}
+
+	n, err := getNode(gopt.programTree, "cmd1", "sub1cmd1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sub, ok := n.ChildOptions["sub1cmd1opt1"]
+	if !ok || !sub.ConfigCalled {
+		t.Fatalf("sub1cmd1opt1 not seeded from config")
+	}
+}
+
+func TestLoadConfigReaderTOML(t *testing.T) {
+	gopt := setupOpt()
+
+	toml := "rootopt1 = \"hello\"\n\n[cmd1.sub1cmd1]\nsub1cmd1opt1 = \"world\"\n"
+	err := gopt.LoadConfigReader(strings.NewReader(toml), ConfigTOML)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opt, ok := gopt.programTree.ChildOptions["rootopt1"]
+	if !ok || !opt.ConfigCalled {
+		t.Fatalf("rootopt1 not seeded from config")
+	}
+
+	n, err := getNode(gopt.programTree, "cmd1", "sub1cmd1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sub, ok := n.ChildOptions["sub1cmd1opt1"]
+	if !ok || !sub.ConfigCalled {
+		t.Fatalf("sub1cmd1opt1 not seeded from config")
+	}
+}
+
+func TestLoadConfigReaderYAML(t *testing.T) {
+	gopt := setupOpt()
+
+	yaml := "rootopt1: hello\ncmd1:\n  sub1cmd1:\n    sub1cmd1opt1: world\n"
+	err := gopt.LoadConfigReader(strings.NewReader(yaml), ConfigYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opt, ok := gopt.programTree.ChildOptions["rootopt1"]
+	if !ok || !opt.ConfigCalled {
+		t.Fatalf("rootopt1 not seeded from config")
+	}
+
+	n, err := getNode(gopt.programTree, "cmd1", "sub1cmd1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sub, ok := n.ChildOptions["sub1cmd1opt1"]
+	if !ok || !sub.ConfigCalled {
+		t.Fatalf("sub1cmd1opt1 not seeded from config")
+	}
+}
+
+func TestLoadConfigReaderRepeatedKeyLastValueWins(t *testing.T) {
+	gopt := setupOpt()
+
+	ini := "rootopt1 = one\nrootopt1 = two\nrootopt1 = three\n"
+	err := gopt.LoadConfigReader(strings.NewReader(ini), ConfigINI)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opt, ok := gopt.programTree.ChildOptions["rootopt1"]
+	if !ok {
+		t.Fatalf("rootopt1 not found")
+	}
+	if opt.value != "three" {
+		t.Fatalf("expected last repeated value to win, got %q", opt.value)
+	}
+}
+
+func TestLoadConfigReaderRepeatedKeyAppendsToBoundSlice(t *testing.T) {
+	type cfg struct {
+		Tags []string `long:"tag"`
+	}
+	var c cfg
+	gopt, err := NewFromStruct(&c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ini := "tag = one\ntag = two\ntag = three\n"
+	if err := gopt.LoadConfigReader(strings.NewReader(ini), ConfigINI); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"one", "two", "three"}
+	if len(c.Tags) != len(want) {
+		t.Fatalf("expected %v, got %v", want, c.Tags)
+	}
+	for i, v := range want {
+		if c.Tags[i] != v {
+			t.Fatalf("expected %v, got %v", want, c.Tags)
+		}
+	}
+}
+
+func TestLoadConfigReaderTOMLRejectsArray(t *testing.T) {
+	gopt := setupOpt()
+
+	toml := "tags = [\"a\", \"b\"]\n"
+	if err := gopt.LoadConfigReader(strings.NewReader(toml), ConfigTOML); err == nil {
+		t.Fatalf("expected an error instead of silently misreading a TOML array")
+	}
+}
+
+func TestLoadConfigReaderTOMLRejectsArrayOfTables(t *testing.T) {
+	gopt := setupOpt()
+
+	toml := "[[cmd1]]\nrootopt1 = hello\n"
+	if err := gopt.LoadConfigReader(strings.NewReader(toml), ConfigTOML); err == nil {
+		t.Fatalf("expected an error instead of silently misreading a TOML array-of-tables header")
+	}
+}
+
+func TestLoadConfigReaderYAMLRejectsList(t *testing.T) {
+	gopt := setupOpt()
+
+	yaml := "tags:\n  - a\n  - b\n"
+	if err := gopt.LoadConfigReader(strings.NewReader(yaml), ConfigYAML); err == nil {
+		t.Fatalf("expected an error instead of silently misreading a YAML list")
+	}
+}
+
+func TestLoadConfigReaderYAMLRejectsFlowStyle(t *testing.T) {
+	gopt := setupOpt()
+
+	yaml := "rootopt1: {a: 1}\n"
+	if err := gopt.LoadConfigReader(strings.NewReader(yaml), ConfigYAML); err == nil {
+		t.Fatalf("expected an error instead of silently misreading YAML flow style")
+	}
+}
+
+func TestLoadConfigReaderCLIOverridesConfig(t *testing.T) {
+	gopt := setupOpt()
+	opt := gopt.programTree.ChildOptions["rootopt1"]
+	opt.Called = true
+	opt.Save("from-cli")
+
+	err := gopt.LoadConfigReader(strings.NewReader("rootopt1 = from-config\n"), ConfigINI)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if opt.ConfigCalled {
+		t.Fatalf("config must not override a value already set on the CLI")
+	}
+}
@@ -0,0 +1,54 @@
+package getoptions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decodeTOML is a hand-rolled line scanner, not a TOML parser: there is no
+// TOML library vendored for this package, so it only understands
+// `[table.sub]` headers and `key = "value"` / `key = value` lines, the same
+// shape decodeINI produces. Arrays-of-tables, inline tables, arrays and
+// multi-line strings are rejected with an error instead of being silently
+// misread. Other constructs are not detected: a trailing `# comment` after a
+// value, for example, is kept as part of the value.
+func decodeTOML(r io.Reader) ([]configEntry, error) {
+	var entries []configEntry
+	var path []string
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("config line %d: arrays of tables are not supported by this hand-rolled decoder, got %q", lineNo, line)
+			}
+			table := strings.TrimSpace(line[1 : len(line)-1])
+			if table == "" {
+				path = nil
+			} else {
+				path = strings.Split(table, ".")
+			}
+			continue
+		}
+		key, value, ok := splitKV(line)
+		if !ok {
+			return nil, fmt.Errorf("config line %d: expected 'key = value', got %q", lineNo, line)
+		}
+		if err := rejectFlowStyleValue(lineNo, value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, configEntry{path: append([]string{}, path...), key: key, value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,66 @@
+package getoptions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decodeYAML is a hand-rolled line scanner, not a YAML parser: there is no
+// YAML library vendored for this package, so it only understands two-space
+// indented nested maps, where each level of nesting becomes a path segment
+// (mirroring the `[cmd1.sub1cmd1]` section syntax of decodeINI) and a
+// `key: value` leaf becomes a configEntry. Lists (`- item`), anchors/aliases
+// and flow style are rejected with an error instead of being silently
+// misread. Other constructs are not detected: a block scalar indicator such
+// as `key: |`, for example, is taken as the literal value "|".
+func decodeYAML(r io.Reader) ([]configEntry, error) {
+	var entries []configEntry
+	var stack []string
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			return nil, fmt.Errorf("config line %d: YAML lists are not supported by this hand-rolled decoder, got %q", lineNo, trimmed)
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if indent%2 != 0 {
+			return nil, fmt.Errorf("config line %d: odd indentation %q", lineNo, raw)
+		}
+		depth := indent / 2
+		if depth > len(stack) {
+			return nil, fmt.Errorf("config line %d: unexpected indentation %q", lineNo, raw)
+		}
+		stack = stack[:depth]
+
+		key, value, ok := splitKV(trimmed)
+		if !ok {
+			return nil, fmt.Errorf("config line %d: expected 'key: value', got %q", lineNo, trimmed)
+		}
+		if value == "" {
+			// A bare `key:` opens a nested map; subsequent deeper lines
+			// address the command path through this key.
+			stack = append(stack, key)
+			continue
+		}
+		if err := rejectFlowStyleValue(lineNo, value); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(value, "&") || strings.HasPrefix(value, "*") {
+			return nil, fmt.Errorf("config line %d: YAML anchors/aliases are not supported by this hand-rolled decoder, got %q", lineNo, value)
+		}
+		entries = append(entries, configEntry{path: append([]string{}, stack...), key: key, value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
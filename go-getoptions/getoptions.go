@@ -0,0 +1,131 @@
+// Package getoptions implements a command/option parser built around a
+// programTree: a root node for the program itself, with subcommands and
+// options registered onto it (or onto the Command returned for a
+// subcommand) before Parse walks the actual CLI args against that tree.
+package getoptions
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+)
+
+// Logger is the package's diagnostic logger. Tests redirect it with
+// SetOutput so anything logged is captured instead of reaching stderr; the
+// parser itself does not write to it.
+var Logger = log.New(os.Stderr, "[getoptions] ", log.LstdFlags)
+
+// ErrorMissingArgument is returned when an option that requires a value
+// reaches the end of args, or the next token looks like another option
+// rather than a value, without one.
+var ErrorMissingArgument = errors.New("missing argument")
+
+// Mode selects how parseCLIArgs treats the args it is given. Normal is the
+// only mode implemented so far: argument tokens are matched against
+// commands/options positionally, left to right.
+type Mode int
+
+// Normal is the default Mode: no special dialect, just command/option/text
+// resolution against the programTree.
+const Normal Mode = iota
+
+// GetOpt is the root of a command tree: the entry point for registering
+// options/subcommands (NewOption, NewCommand) and for running Parse against
+// a real argument list.
+type GetOpt struct {
+	programTree *programTree
+
+	// ResponseFilePrefix/ResponseFS configure @file argument expansion; see
+	// SetResponseFilePrefix in responsefile.go. Zero value (prefix 0)
+	// disables expansion.
+	ResponseFilePrefix rune
+	ResponseFS         fs.FS
+
+	// usageValidation holds the repeat/mutual-exclusion constraints
+	// recorded by NewFromUsage, checked by ValidateUsage after Parse.
+	usageValidation []usageValidation
+}
+
+// Command is a subcommand node returned by NewCommand, through which
+// further options/subcommands are registered.
+type Command struct {
+	programTree *programTree
+}
+
+// New creates an empty GetOpt rooted at a fresh programTree.
+func New() *GetOpt {
+	name := "prog"
+	if len(os.Args) > 0 {
+		name = os.Args[0]
+	}
+	return &GetOpt{
+		programTree: &programTree{
+			Type:          argTypeProgname,
+			Name:          name,
+			ChildCommands: map[string]*programTree{},
+			ChildOptions:  map[string]*Option{},
+		},
+	}
+}
+
+// NewOption registers a value-taking option named name at the root of
+// gopt's command tree.
+func (gopt *GetOpt) NewOption(name, def string) *Option {
+	return gopt.programTree.NewOption(name, def)
+}
+
+// NewCommand registers a subcommand named name at the root of gopt's
+// command tree and returns it for further registration.
+func (gopt *GetOpt) NewCommand(name, description string) *Command {
+	return &Command{programTree: gopt.programTree.NewCommand(name, description)}
+}
+
+// NewOption registers a value-taking option named name on c.
+func (c *Command) NewOption(name, def string) *Option {
+	return c.programTree.NewOption(name, def)
+}
+
+// NewCommand registers a subcommand named name under c and returns it for
+// further registration.
+func (c *Command) NewCommand(name, description string) *Command {
+	return &Command{programTree: c.programTree.NewCommand(name, description)}
+}
+
+// tree returns gopt's underlying programTree node; see commandAdder.
+func (gopt *GetOpt) tree() *programTree { return gopt.programTree }
+
+// tree returns c's underlying programTree node; see commandAdder.
+func (c *Command) tree() *programTree { return c.programTree }
+
+// Parse runs args through the command tree: it resolves options/subcommands
+// left to right, populating Called/UsedAlias/value on the Options touched
+// and ChildText on whichever node is reached, and returns that node.
+//
+// If SetResponseFilePrefix was called, args is first expanded through
+// expandResponseFiles, so a "@file" token on the real command line is
+// spliced in before anything else runs. Once parsing succeeds, every option
+// marked SetRequired on the landed node or one of its ancestors must have
+// been supplied by the CLI (Called), a config file (ConfigCalled) or an env
+// tag (EnvCalled), or Parse returns an error without running ValidateUsage. If
+// gopt was built by NewFromUsage, the parse is then checked against
+// ValidateUsage, so the repeat/mutual-exclusion constraints recorded from the
+// usage string are enforced without the caller having to remember to call
+// ValidateUsage separately.
+func (gopt *GetOpt) Parse(args []string) (*programTree, error) {
+	args, err := expandResponseFiles(gopt.ResponseFS, gopt.ResponseFilePrefix, args)
+	if err != nil {
+		return nil, err
+	}
+	n, _, err := parseCLIArgs(false, gopt.programTree, args, Normal)
+	if err != nil {
+		return n, err
+	}
+	if err := checkRequiredOptions(n); err != nil {
+		return n, err
+	}
+	if err := gopt.ValidateUsage(n); err != nil {
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,46 @@
+package getoptions
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// setupOpt builds the fixture tree shared by this package's tests:
+//
+//	prog --rootopt1=<v>
+//	  cmd1 --cmd1opt1=<v>
+//	    sub1cmd1 --sub1cmd1opt1=<v>
+//	    sub2cmd1 -
+//	  cmd2
+//
+// rootopt1 and cmd1opt1 are Persistent, so they also resolve from any
+// descendant of the node they're declared on.
+func setupOpt() *GetOpt {
+	gopt := New()
+	gopt.NewOption("rootopt1", "").Persistent()
+	cmd1 := gopt.NewCommand("cmd1", "")
+	cmd1.NewOption("cmd1opt1", "").Persistent()
+	sub1cmd1 := cmd1.NewCommand("sub1cmd1", "")
+	sub1cmd1.NewOption("sub1cmd1opt1", "")
+	sub2cmd1 := cmd1.NewCommand("sub2cmd1", "")
+	sub2cmd1.NewOption("-", "").NoArg()
+	gopt.NewCommand("cmd2", "")
+	return gopt
+}
+
+// SpewToFile dumps v to a temp file under t's t.TempDir(), for inclusion in
+// a failure message without flooding the test output itself, and returns
+// the path it wrote to.
+func SpewToFile(t *testing.T, v interface{}, name string) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), name+"-*.txt")
+	if err != nil {
+		t.Fatalf("SpewToFile: %s", err)
+	}
+	defer f.Close()
+	fmt.Fprint(f, spew.Sdump(v))
+	return f.Name()
+}
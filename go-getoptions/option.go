@@ -0,0 +1,85 @@
+package getoptions
+
+// Option is a single flag registered on a programTree node, reached through
+// NewOption. The builder methods (Description, SetRequired, SetAlias,
+// OnSave, Persistent) all return opt so calls can be chained off NewOption,
+// mirroring the rest of the package's fluent style.
+type Option struct {
+	Name      string
+	Aliases   []string
+	Called    bool
+	UsedAlias string
+
+	// ConfigCalled is set by LoadConfigReader when a config file, rather
+	// than the command line, supplied the value.
+	ConfigCalled bool
+
+	// EnvCalled is set by NewFromStruct's env tag handling when an
+	// environment variable, rather than the command line, supplied the
+	// value.
+	EnvCalled bool
+
+	// IsPersistent marks opt for propagation into every descendant command
+	// node. See Persistent and registerPersistentOptions in persistent.go.
+	IsPersistent bool
+
+	// ArgsNeeded is the number of CLI tokens parseCLIArgs must consume as
+	// this option's argument. Options built through NewOption default to 1
+	// (take a value); NoArg marks a boolean-style flag that takes none.
+	ArgsNeeded int
+
+	description string
+	required    bool
+	requiredMsg string
+
+	value  string
+	onSave func(string) error
+}
+
+// Save records value as opt's current value, running the OnSave callback
+// (if any) first so a callback error leaves opt unchanged.
+func (opt *Option) Save(value string) error {
+	if opt.onSave != nil {
+		if err := opt.onSave(value); err != nil {
+			return err
+		}
+	}
+	opt.value = value
+	return nil
+}
+
+// OnSave registers fn to run whenever Save is called, e.g. to copy the
+// value into a bound struct field. It replaces any previously registered
+// callback.
+func (opt *Option) OnSave(fn func(string) error) *Option {
+	opt.onSave = fn
+	return opt
+}
+
+// Description sets the text shown for opt in generated help/usage output.
+func (opt *Option) Description(d string) *Option {
+	opt.description = d
+	return opt
+}
+
+// SetRequired marks opt as required; msg overrides the default "missing
+// required option" message when non-empty.
+func (opt *Option) SetRequired(msg string) *Option {
+	opt.required = true
+	opt.requiredMsg = msg
+	return opt
+}
+
+// SetAlias adds additional names that also resolve to opt.
+func (opt *Option) SetAlias(aliases ...string) *Option {
+	opt.Aliases = append(opt.Aliases, aliases...)
+	return opt
+}
+
+// NoArg marks opt as a boolean-style flag: parseCLIArgs sets Called/UsedAlias
+// when it sees opt's name on the command line but does not consume a
+// following token as its value.
+func (opt *Option) NoArg() *Option {
+	opt.ArgsNeeded = 0
+	return opt
+}
@@ -0,0 +1,221 @@
+package getoptions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// completions is the result of a completion-mode parseCLIArgs call: the
+// list of strings a shell completion script should offer for the token the
+// caller is still typing.
+type completions *[]string
+
+// parseCLIArgs walks tree against args, left to right:
+//   - "--" stops option/command matching; everything after it is text.
+//   - a token starting with "-" is resolved against the current node's
+//     ChildOptions (including any inherited through NewCommand); an option
+//     that needs a value consumes the next token, unless that token is
+//     missing or itself looks like an option, which is ErrorMissingArgument;
+//     outside completion mode, a miss is ErrorUnknownOption, but in
+//     completion mode parsing stops here instead so a partially-typed flag
+//     falls through to computeCompletions rather than erroring.
+//   - otherwise, an exact match in the current node's ChildCommands
+//     descends into that subcommand; outside completion mode, a near-miss
+//     (within maxSuggestionDistance of a sibling command name) is
+//     ErrorUnknownCommand instead, so a typo doesn't silently turn into a
+//     positional argument; anything else is appended to the current node's
+//     ChildText.
+//
+// When completionMode is true, parseCLIArgs also computes the shell
+// completion candidates for the last token in args (see computeCompletions);
+// when it is false this is always an empty, non-nil list.
+func parseCLIArgs(completionMode bool, tree *programTree, args []string, mode Mode) (*programTree, completions, error) {
+	node := tree
+	terminatorSeen := false
+
+	haveLast := len(args) > 0
+	nodeBeforeLast := tree
+	lastToken := ""
+	terminatorBeforeLast := false
+
+	mark := func(i int, node *programTree) {
+		if i == len(args)-1 {
+			nodeBeforeLast = node
+			lastToken = args[i]
+			terminatorBeforeLast = terminatorSeen
+		}
+	}
+
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+		mark(i, node)
+
+		if !terminatorSeen && arg == "--" {
+			terminatorSeen = true
+			i++
+			continue
+		}
+
+		if !terminatorSeen && looksLikeOption(arg) {
+			name, inline, hasInline := splitOptionArg(arg)
+			opt, ok := lookupOption(node, name)
+			if !ok {
+				if !completionMode {
+					return node, emptyCompletions(), newErrorUnknownOption(name, optionNames(node), fmt.Errorf("not defined on %s", node.Name))
+				}
+				break
+			}
+			opt.Called = true
+			opt.UsedAlias = name
+			i++
+			if opt.ArgsNeeded == 0 {
+				continue
+			}
+			if hasInline {
+				if err := opt.Save(inline); err != nil {
+					return node, emptyCompletions(), err
+				}
+				continue
+			}
+			if i >= len(args) || looksLikeOption(args[i]) {
+				return node, emptyCompletions(), ErrorMissingArgument
+			}
+			mark(i, node)
+			if err := opt.Save(args[i]); err != nil {
+				return node, emptyCompletions(), err
+			}
+			i++
+			continue
+		}
+
+		if !terminatorSeen {
+			if child, ok := node.ChildCommands[arg]; ok {
+				node = child
+				i++
+				continue
+			}
+			candidates := commandNames(node)
+			if !completionMode && len(suggest(arg, candidates)) > 0 {
+				return node, emptyCompletions(), newErrorUnknownCommand(arg, candidates, fmt.Errorf("not defined on %s", node.Name))
+			}
+		}
+
+		value := arg
+		node.ChildText = append(node.ChildText, &value)
+		i++
+	}
+
+	if !completionMode {
+		return node, emptyCompletions(), nil
+	}
+	if !haveLast {
+		nodeBeforeLast = tree
+		lastToken = ""
+	}
+	if terminatorBeforeLast {
+		return node, emptyCompletions(), nil
+	}
+	return node, computeCompletions(nodeBeforeLast, lastToken), nil
+}
+
+// looksLikeOption reports whether arg should be resolved against
+// ChildOptions rather than treated as a command/text token or a value.
+func looksLikeOption(arg string) bool {
+	return len(arg) > 0 && arg[0] == '-'
+}
+
+// lookupOption resolves name against node's ChildOptions, falling back to a
+// scan of each Option's Aliases (set by SetAlias) so a short/long pair or any
+// other alias registered under a single Option is reachable by either name.
+func lookupOption(node *programTree, name string) (*Option, bool) {
+	if opt, ok := node.ChildOptions[name]; ok {
+		return opt, true
+	}
+	for _, opt := range node.ChildOptions {
+		for _, alias := range opt.Aliases {
+			if alias == name {
+				return opt, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// splitOptionArg splits a "--name", "--name=value", "-n" or "-" token into
+// the bare option name and, if present, its inline value.
+func splitOptionArg(arg string) (name, inline string, hasInline bool) {
+	if arg == "-" {
+		return "-", "", false
+	}
+	rest := strings.TrimPrefix(arg, "--")
+	rest = strings.TrimPrefix(rest, "-")
+	if eq := strings.IndexByte(rest, '='); eq >= 0 {
+		return rest[:eq], rest[eq+1:], true
+	}
+	return rest, "", false
+}
+
+func emptyCompletions() completions {
+	out := []string{}
+	return &out
+}
+
+// computeCompletions returns the candidates for the token a completion
+// script is still typing: option names if it starts with "-", otherwise
+// subcommand names of node, both prefix-filtered against lastToken. A
+// single match is returned with a trailing space (the token is complete);
+// multiple matches are returned as-is for the shell to disambiguate.
+func computeCompletions(node *programTree, lastToken string) completions {
+	if strings.HasPrefix(lastToken, "-") {
+		return completeNames(optionDisplayNames(node), lastToken)
+	}
+	return completeNames(commandNames(node), lastToken)
+}
+
+func completeNames(names []string, prefix string) completions {
+	matches := make([]string, 0, len(names))
+	for _, n := range names {
+		if strings.HasPrefix(n, prefix) {
+			matches = append(matches, n)
+		}
+	}
+	sort.Strings(matches)
+	if len(matches) == 1 {
+		out := []string{matches[0] + " "}
+		return &out
+	}
+	return &matches
+}
+
+// optionNames returns the bare (un-prefixed) names of n's ChildOptions, the
+// candidate shape newErrorUnknownOption expects; shared by parseCLIArgs and
+// LoadConfigReader so both report the same suggestions for the same node.
+func optionNames(n *programTree) []string {
+	names := make([]string, 0, len(n.ChildOptions))
+	for name := range n.ChildOptions {
+		names = append(names, name)
+	}
+	return names
+}
+
+func optionDisplayNames(n *programTree) []string {
+	names := make([]string, 0, len(n.ChildOptions))
+	for name := range n.ChildOptions {
+		if name == "-" {
+			names = append(names, name)
+			continue
+		}
+		names = append(names, "--"+name)
+	}
+	return names
+}
+
+func commandNames(n *programTree) []string {
+	names := make([]string, 0, len(n.ChildCommands))
+	for name := range n.ChildCommands {
+		names = append(names, name)
+	}
+	return names
+}
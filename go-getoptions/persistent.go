@@ -0,0 +1,42 @@
+package getoptions
+
+import "fmt"
+
+// Persistent marks opt as inherited by every descendant of the node it was
+// declared on: parseCLIArgs finds it in a child's own ChildOptions, which is
+// how the "root option to command" and "root option to subcommand" fixtures
+// resolve rootopt1 (setupOpt marks it Persistent); parseCLIArgs itself never
+// looks at a parent's ChildOptions. There is a single option underneath, so
+// opt.Called and opt.Save are observable from the declaring node or any
+// descendant.
+//
+// Propagation happens once, when NewCommand creates a subcommand node, so
+// opt must be marked Persistent before any subcommand that should inherit it
+// is created; a subcommand registered earlier does not retroactively pick up
+// a Persistent option declared on its parent afterwards.
+//
+// Persistent returns opt so it can be chained off NewOption, mirroring the
+// rest of the Option builder methods.
+func (opt *Option) Persistent() *Option {
+	opt.IsPersistent = true
+	return opt
+}
+
+// registerPersistentOptions copies every persistent option declared on an
+// ancestor of n into n.ChildOptions, so the plain map lookups in
+// parseCLIArgs and the completion path keep working unmodified. It is called
+// while building the tree (NewCommand) rather than during parsing so a single
+// *Option is shared by reference at every level - Save on one node's copy is
+// visible from all of them.
+func registerPersistentOptions(parent, n *programTree) error {
+	for name, opt := range parent.ChildOptions {
+		if !opt.IsPersistent {
+			continue
+		}
+		if existing, ok := n.ChildOptions[name]; ok && existing != opt {
+			return fmt.Errorf("option %q is already defined on %s and conflicts with the persistent option declared on %s", name, n.Str(), parent.Str())
+		}
+		n.ChildOptions[name] = opt
+	}
+	return nil
+}
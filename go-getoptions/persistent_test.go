@@ -0,0 +1,94 @@
+package getoptions
+
+import "testing"
+
+func TestPersistentOptionVisibleInDescendants(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("rootopt1", "").Persistent()
+	cmd1 := gopt.NewCommand("cmd1", "")
+	sub1cmd1 := cmd1.NewCommand("sub1cmd1", "")
+
+	if err := registerPersistentOptions(gopt.programTree, cmd1.programTree); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := registerPersistentOptions(cmd1.programTree, sub1cmd1.programTree); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opt, ok := sub1cmd1.programTree.ChildOptions["rootopt1"]
+	if !ok {
+		t.Fatalf("rootopt1 not inherited by sub1cmd1")
+	}
+
+	opt.Called = true
+	opt.Save("hello")
+
+	root, ok := gopt.programTree.ChildOptions["rootopt1"]
+	if !ok || !root.Called {
+		t.Fatalf("saving through the descendant must be observable at the root: %#v", root)
+	}
+}
+
+func TestNewCommandPropagatesPersistentOptions(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("rootopt1", "").Persistent()
+	cmd1 := gopt.NewCommand("cmd1", "")
+	sub1cmd1 := cmd1.NewCommand("sub1cmd1", "")
+
+	opt, ok := sub1cmd1.programTree.ChildOptions["rootopt1"]
+	if !ok {
+		t.Fatalf("NewCommand did not propagate rootopt1 down to sub1cmd1: %#v", sub1cmd1.programTree)
+	}
+
+	node, err := gopt.Parse([]string{"cmd1", "sub1cmd1", "--rootopt1", "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.Name != "sub1cmd1" {
+		t.Fatalf("expected to land on sub1cmd1, got %s", node.Name)
+	}
+	if !opt.Called {
+		t.Fatalf("--rootopt1 parsed from sub1cmd1 must mark the single shared Option as Called")
+	}
+}
+
+func TestPersistentOptionDeclaredAfterCommandDoesNotPropagate(t *testing.T) {
+	gopt := New()
+	cmd1 := gopt.NewCommand("cmd1", "")
+	gopt.NewOption("rootopt1", "").Persistent()
+
+	if _, ok := cmd1.programTree.ChildOptions["rootopt1"]; ok {
+		t.Fatalf("rootopt1 was declared Persistent after cmd1 already existed; it must not have propagated")
+	}
+}
+
+func TestPersistentOptionConflict(t *testing.T) {
+	// cmd1.ChildOptions is populated directly, bypassing NewOption, since
+	// NewOption itself now refuses (by panicking, see
+	// TestNewOptionPanicsOnPersistentConflict) to let a caller redefine a
+	// name inherited from a persistent option. registerPersistentOptions
+	// keeps its own conflict check for a caller that passes it a node
+	// already holding a different option under the same name, as done here.
+	gopt := New()
+	gopt.NewOption("rootopt1", "").Persistent()
+	cmd1 := gopt.NewCommand("cmd1", "")
+	cmd1.programTree.ChildOptions["rootopt1"] = &Option{Name: "rootopt1"}
+
+	err := registerPersistentOptions(gopt.programTree, cmd1.programTree)
+	if err == nil {
+		t.Fatalf("expected a conflict error when cmd1 redefines rootopt1")
+	}
+}
+
+func TestNewOptionPanicsOnPersistentConflict(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("rootopt1", "").Persistent()
+	cmd1 := gopt.NewCommand("cmd1", "")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected NewOption to panic when cmd1 redeclares the persistent rootopt1 it inherited")
+		}
+	}()
+	cmd1.NewOption("rootopt1", "")
+}
@@ -0,0 +1,117 @@
+package getoptions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// argType classifies a programTree node.
+type argType int
+
+const (
+	argTypeProgname argType = iota
+	argTypeCommand
+	argTypeOption
+	argTypeText
+)
+
+// programTree is one node of the command tree built by NewCommand/NewOption:
+// the root node (argTypeProgname) for the program itself, one argTypeCommand
+// node per registered subcommand. parseCLIArgs walks this tree against the
+// CLI args, descending into ChildCommands on an exact name match, resolving
+// "--flag"/"-f" tokens against ChildOptions, and appending anything left
+// over to ChildText.
+type programTree struct {
+	Type        argType
+	Name        string
+	Description string
+	Parent      *programTree
+
+	ChildCommands map[string]*programTree
+	ChildOptions  map[string]*Option
+	ChildText     []*string
+}
+
+// NewOption registers a value-taking option named name on n, seeded with
+// def as its value until Save is called. It returns the *Option for
+// chaining (Description, SetRequired, Persistent, ...).
+//
+// If name is already registered on n because it was inherited from a
+// Persistent option on an ancestor (see registerPersistentOptions), this
+// panics rather than silently replacing the shared *Option: doing so would
+// sever that option's propagation to n's own descendants and any
+// already-taken Called/Save state, for no indication to the caller that
+// anything happened.
+func (n *programTree) NewOption(name, def string) *Option {
+	if existing, ok := n.ChildOptions[name]; ok && existing.IsPersistent {
+		panic(fmt.Sprintf("getoptions: %q is already registered on %s as a persistent option inherited from an ancestor; declaring a new option with the same name would silently replace it", name, n.Name))
+	}
+	opt := &Option{Name: name, value: def, ArgsNeeded: 1}
+	n.ChildOptions[name] = opt
+	return opt
+}
+
+// NewCommand registers a subcommand named name under n and returns the node
+// for that subcommand. Any option already declared on n (or further up) via
+// Persistent is propagated into the new node's ChildOptions, by reference,
+// the same way registerPersistentOptions does when called directly; plain
+// (non-persistent) options do not carry over and only resolve on the node
+// they were declared on. This propagation only looks at options declared on
+// n before NewCommand is called - mark an option Persistent before
+// registering the subcommands that should see it, not after.
+func (n *programTree) NewCommand(name, description string) *programTree {
+	child := &programTree{
+		Type:          argTypeCommand,
+		Name:          name,
+		Description:   description,
+		Parent:        n,
+		ChildCommands: map[string]*programTree{},
+		ChildOptions:  map[string]*Option{},
+	}
+	n.ChildCommands[name] = child
+	if err := registerPersistentOptions(n, child); err != nil {
+		// child was just created with an empty ChildOptions, so there is
+		// nothing on it for a persistent option to conflict with; an error
+		// here is a programming error in this package, not a runtime
+		// condition to recover from.
+		panic(err)
+	}
+	return child
+}
+
+// Str renders n and its subtree for debugging/error messages.
+func (n *programTree) Str() string {
+	var b strings.Builder
+	n.writeStr(&b, 0)
+	return b.String()
+}
+
+func (n *programTree) writeStr(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(b, "%s%s\n", indent, n.Name)
+	for name, opt := range n.ChildOptions {
+		fmt.Fprintf(b, "%s  --%s called=%v\n", indent, name, opt.Called)
+	}
+	for _, t := range n.ChildText {
+		fmt.Fprintf(b, "%s  text=%s\n", indent, *t)
+	}
+	for _, c := range n.ChildCommands {
+		c.writeStr(b, depth+1)
+	}
+}
+
+// getNode walks tree through ChildCommands following path, the same way a
+// CLI invocation descends into nested subcommands. It is used by
+// getoptions call sites (LoadConfigReader section paths, tests) that need
+// to address a node by name without parsing real CLI args.
+func getNode(tree *programTree, path ...string) (*programTree, error) {
+	n := tree
+	for _, name := range path {
+		child, ok := n.ChildCommands[name]
+		if !ok {
+			return nil, fmt.Errorf("command %q not found under %s", name, n.Name)
+		}
+		n = child
+	}
+	return n, nil
+}
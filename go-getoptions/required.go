@@ -0,0 +1,41 @@
+package getoptions
+
+import (
+	"fmt"
+	"sort"
+)
+
+// checkRequiredOptions walks n and then each of its ancestors, in turn,
+// looking for an Option marked SetRequired that was satisfied by none of the
+// CLI (Called), a config file (ConfigCalled), or a struct tag's env var
+// (EnvCalled), the same way a persistent option is resolved across levels. A
+// shared *Option reachable from more than one of those nodes (a persistent
+// option propagated by NewCommand) is only checked once. Names are checked
+// in sorted order so the reported violation is deterministic when more than
+// one option is missing. It returns the first violation found; requiredMsg
+// overrides the default message when set.
+func checkRequiredOptions(n *programTree) error {
+	seen := map[*Option]bool{}
+	for cur := n; cur != nil; cur = cur.Parent {
+		names := make([]string, 0, len(cur.ChildOptions))
+		for name := range cur.ChildOptions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			opt := cur.ChildOptions[name]
+			if seen[opt] {
+				continue
+			}
+			seen[opt] = true
+			if !opt.required || opt.Called || opt.ConfigCalled || opt.EnvCalled {
+				continue
+			}
+			if opt.requiredMsg != "" {
+				return fmt.Errorf("%s", opt.requiredMsg)
+			}
+			return fmt.Errorf("missing required option: %s", opt.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package getoptions
+
+import "testing"
+
+func TestParseFailsOnMissingRequiredOption(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("repo", "").SetRequired("")
+
+	if _, err := gopt.Parse(nil); err == nil {
+		t.Fatalf("expected an error when a required option is never Called")
+	}
+}
+
+func TestParseSucceedsWhenRequiredOptionIsCalled(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("repo", "").SetRequired("")
+
+	if _, err := gopt.Parse([]string{"--repo", "myrepo"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestParseUsesRequiredMsgWhenSet(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("repo", "").SetRequired("--repo is mandatory")
+
+	_, err := gopt.Parse(nil)
+	if err == nil {
+		t.Fatalf("expected an error when a required option is never Called")
+	}
+	if err.Error() != "--repo is mandatory" {
+		t.Errorf("expected the custom requiredMsg, got %q", err.Error())
+	}
+}
+
+func TestParseFailsOnMissingPersistentRequiredOption(t *testing.T) {
+	gopt := New()
+	gopt.NewOption("repo", "").SetRequired("").Persistent()
+	gopt.NewCommand("cmd1", "")
+
+	if _, err := gopt.Parse([]string{"cmd1"}); err == nil {
+		t.Fatalf("expected an error when a persistent required option is never Called from a descendant command")
+	}
+}
@@ -0,0 +1,174 @@
+package getoptions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// maxResponseFileDepth bounds how many levels of nested @file references
+// expandResponseFiles will follow before giving up, so a cycle (or a chain
+// that is simply too deep to be a mistake) fails fast instead of hanging.
+const maxResponseFileDepth = 10
+
+// SetResponseFilePrefix opts gopt into @file argument expansion: any element
+// of the args slice passed to Parse that starts with prefix is replaced, in
+// place, by the shell-split tokens read from the file named by the rest of
+// the element. Pass 0 to disable expansion again.
+//
+// Tokens after a "--" terminator are left untouched, matching the semantics
+// of the "terminator" case in TestParseCLIArgs.
+func (gopt *GetOpt) SetResponseFilePrefix(prefix rune) *GetOpt {
+	gopt.ResponseFilePrefix = prefix
+	return gopt
+}
+
+// ResponseFileSystem lets tests (or callers embedding config alongside their
+// binary) supply a virtual filesystem for @file lookups instead of the OS
+// filesystem SetResponseFilePrefix uses by default.
+func (gopt *GetOpt) ResponseFileSystem(fsys fs.FS) *GetOpt {
+	gopt.ResponseFS = fsys
+	return gopt
+}
+
+// expandResponseFiles scans args for elements prefixed with prefix and
+// splices in the tokens read from the named file, recursively, until no
+// prefixed element remains or maxResponseFileDepth is exceeded. It stops
+// scanning at a bare "--" so terminator semantics are unaffected.
+func expandResponseFiles(fsys fs.FS, prefix rune, args []string) ([]string, error) {
+	if prefix == 0 {
+		return args, nil
+	}
+	return expandResponseFilesDepth(fsys, prefix, args, nil, 0)
+}
+
+func expandResponseFilesDepth(fsys fs.FS, prefix rune, args []string, seen []string, depth int) ([]string, error) {
+	if depth > maxResponseFileDepth {
+		return nil, fmt.Errorf("response file expansion exceeded depth %d, possible cycle in %v", maxResponseFileDepth, seen)
+	}
+
+	var out []string
+	for i, arg := range args {
+		if arg == "--" {
+			out = append(out, args[i:]...)
+			return out, nil
+		}
+		if len(arg) == 0 || rune(arg[0]) != prefix {
+			out = append(out, arg)
+			continue
+		}
+
+		path := arg[1:]
+		for _, s := range seen {
+			if s == path {
+				return nil, fmt.Errorf("response file expansion cycle detected: %s", strings.Join(append(seen, path), " -> "))
+			}
+		}
+
+		tokens, err := readResponseFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err := expandResponseFilesDepth(fsys, prefix, tokens, append(seen, path), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+		for _, tok := range expanded {
+			if tok == "--" {
+				// A terminator read from the file ends expansion for the
+				// rest of args too.
+				return append(out, args[i+1:]...), nil
+			}
+		}
+	}
+	return out, nil
+}
+
+// readResponseFile reads path (through fsys, or the OS filesystem if fsys is
+// nil) and shell-splits its contents into tokens: whitespace separates
+// tokens, single/double quotes group a token that contains whitespace, and a
+// backslash escapes the following character. A token may also be given one
+// per line, since a line break is whitespace like any other.
+func readResponseFile(fsys fs.FS, path string) ([]string, error) {
+	var data []byte
+	var err error
+	if fsys != nil {
+		data, err = fs.ReadFile(fsys, path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading response file %s: %w", path, err)
+	}
+	return shellSplit(string(data))
+}
+
+// shellSplit tokenizes s the way a POSIX shell would split an unquoted
+// argument list: runs of whitespace separate tokens, a backslash escapes the
+// next character, and single/double quotes group whitespace into a single
+// token (single quotes take everything literally; double quotes still honor
+// backslash escapes).
+func shellSplit(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	haveToken := false
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			cur.WriteRune(runes[i+1])
+			haveToken = true
+			i++
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("shellSplit: unterminated single quote")
+			}
+			cur.WriteString(string(runes[i+1 : end]))
+			haveToken = true
+			i = end
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					cur.WriteRune(runes[j+1])
+					j += 2
+					continue
+				}
+				cur.WriteRune(runes[j])
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("shellSplit: unterminated double quote")
+			}
+			haveToken = true
+			i = j
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if haveToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				haveToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			haveToken = true
+		}
+	}
+	if haveToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+func indexRune(runes []rune, start int, target rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
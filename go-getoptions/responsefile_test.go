@@ -0,0 +1,93 @@
+package getoptions
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExpandResponseFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"extra.txt":  {Data: []byte("--rootopt1 hello\ncmd1\n")},
+		"nested.txt": {Data: []byte("@extra.txt sub1cmd1")},
+		"cycle.txt":  {Data: []byte("@cycle.txt")},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		wantErr  bool
+	}{
+		{"no prefix present", []string{"cmd1", "txt"}, []string{"cmd1", "txt"}, false},
+		{"expands a response file", []string{"@extra.txt", "txt"}, []string{"--rootopt1", "hello", "cmd1", "txt"}, false},
+		{"expands nested response files", []string{"@nested.txt"}, []string{"--rootopt1", "hello", "cmd1", "sub1cmd1"}, false},
+		{"stops expanding after terminator", []string{"--", "@extra.txt"}, []string{"--", "@extra.txt"}, false},
+		{"detects cycles", []string{"@cycle.txt"}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := expandResponseFiles(fsys, '@', test.args)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(test.expected, got) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseExpandsResponseFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"extra.txt": {Data: []byte("--rootopt1 hello\ncmd1\n")},
+	}
+
+	gopt := setupOpt()
+	gopt.SetResponseFilePrefix('@').ResponseFileSystem(fsys)
+
+	node, err := gopt.Parse([]string{"@extra.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.Name != "cmd1" {
+		t.Fatalf("expected to land on cmd1 via the expanded @file args, got %s", node.Name)
+	}
+	opt, ok := node.ChildOptions["rootopt1"]
+	if !ok || !opt.Called {
+		t.Fatalf("expected rootopt1 to be Called from the expanded @file args: %#v", opt)
+	}
+}
+
+func TestShellSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"simple", "--opt1 value", []string{"--opt1", "value"}},
+		{"one per line", "--opt1\nvalue", []string{"--opt1", "value"}},
+		{"double quoted", `--opt1 "hello world"`, []string{"--opt1", "hello world"}},
+		{"single quoted", `--opt1 'hello world'`, []string{"--opt1", "hello world"}},
+		{"escaped space", `--opt1 hello\ world`, []string{"--opt1", "hello world"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := shellSplit(test.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(test.expected, got) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package getoptions
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+)
+
+// structTag holds the parsed `getoptions` style tags understood by
+// NewFromStruct. Field names follow the tag keys directly so reflection based
+// population can assign back into the struct without an extra lookup table.
+type structTag struct {
+	short       string
+	long        string
+	description string
+	defaultVal  string
+	required    bool
+	env         string
+	command     string
+}
+
+// parseStructTag reads the short/long/description/default/required/env/command
+// keys off a struct field tag. Unknown keys are ignored so additional tags
+// (json, yaml, ...) can coexist on the same field.
+func parseStructTag(tag reflect.StructTag) structTag {
+	st := structTag{
+		short:       tag.Get("short"),
+		long:        tag.Get("long"),
+		description: tag.Get("description"),
+		defaultVal:  tag.Get("default"),
+		env:         tag.Get("env"),
+		command:     tag.Get("command"),
+	}
+	if required, err := strconv.ParseBool(tag.Get("required")); err == nil {
+		st.required = required
+	}
+	return st
+}
+
+// NewFromStruct builds a *GetOpt tree from a struct annotated with
+// `short`, `long`, `description`, `default`, `required`, `env` and `command`
+// tags, in the style of jessevdk/go-flags. Nested structs tagged with
+// `command:"name"` become subcommands registered through NewCommand, mirroring
+// the cmd1/sub1cmd1 fixtures used by TestParseCLIArgs. v must be a pointer to
+// a struct.
+//
+// The returned *GetOpt uses the same programTree/ChildOptions machinery as
+// the rest of the package, so Parse behaves identically to a tree built by
+// hand with NewOption. Call Parse as usual; the struct fields are filled in
+// as a side effect of option.Save during parseCLIArgs.
+func NewFromStruct(v interface{}) (*GetOpt, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("NewFromStruct: v must be a pointer to a struct, got %T", v)
+	}
+	gopt := New()
+	if err := populateFromStruct(gopt, rv.Elem()); err != nil {
+		return nil, err
+	}
+	return gopt, nil
+}
+
+// populateFromStruct walks the fields of rv, registering an option on n for
+// every tagged scalar/slice field and recursing into NewCommand for every
+// field tagged `command:"name"`.
+func populateFromStruct(n commandAdder, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+		tag := parseStructTag(field.Tag)
+
+		if tag.command != "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() != reflect.Struct {
+				return fmt.Errorf("NewFromStruct: field %s tagged command must be a struct", field.Name)
+			}
+			cmd := n.NewCommand(tag.command, tag.description)
+			if err := populateFromStruct(cmd, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag.long == "" && tag.short == "" {
+			continue
+		}
+
+		name := tag.long
+		if name == "" {
+			name = tag.short
+		}
+
+		opt := n.NewOption(name, tag.defaultVal)
+		if tag.short != "" && tag.short != name {
+			opt.SetAlias(tag.short)
+		}
+		opt.Description(tag.description)
+		if tag.required {
+			opt.SetRequired("")
+		}
+		bindStructField(opt, fv)
+		// A scalar field needs this Save to pick up the default at all,
+		// since NewOption only seeds opt's own value, not fv. A slice field
+		// would instead see it as an accumulated entry (bindStructField's
+		// OnSave appends rather than overwrites), double-counting the
+		// default alongside any real CLI/env/config occurrence - skip it
+		// there and leave the slice to start empty.
+		if tag.defaultVal != "" && fv.Kind() != reflect.Slice {
+			if err := opt.Save(tag.defaultVal); err != nil {
+				return fmt.Errorf("NewFromStruct: field %s: %w", field.Name, err)
+			}
+		}
+		if tag.env != "" {
+			if val, ok := os.LookupEnv(tag.env); ok {
+				if err := opt.Save(val); err != nil {
+					return fmt.Errorf("NewFromStruct: field %s: %w", field.Name, err)
+				}
+				opt.EnvCalled = true
+			}
+		}
+	}
+	return nil
+}
+
+// commandAdder is the subset of *GetOpt/*Command used by populateFromStruct
+// and the usage-string builder, narrowed so the same code path handles the
+// root GetOpt and nested commands. tree exposes the underlying programTree
+// node so the usage builder can remember exactly which node a repeat
+// constraint was declared at (see usageValidation.node in usage.go).
+type commandAdder interface {
+	NewOption(name, def string) *Option
+	NewCommand(name, description string) *Command
+	tree() *programTree
+}
+
+// bindStructField registers an OnSave on opt that copies each saved value
+// into fv as Save runs; nothing is copied until the first Save. A string,
+// bool or int field is overwritten, so the last Save wins; a []string field
+// is appended to. Any other field kind makes Save return an error.
+func bindStructField(opt *Option, fv reflect.Value) {
+	opt.OnSave(func(value string) error {
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			fv.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return err
+			}
+			fv.SetInt(i)
+		case reflect.Slice:
+			fv.Set(reflect.Append(fv, reflect.ValueOf(value)))
+		default:
+			return fmt.Errorf("NewFromStruct: unsupported field kind %s", fv.Kind())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,157 @@
+package getoptions
+
+import "testing"
+
+func TestNewFromStructEnvBindsField(t *testing.T) {
+	t.Setenv("GETOPTIONS_TEST_REPO", "fromenv")
+
+	type rootOptions struct {
+		Repo string `long:"repo" env:"GETOPTIONS_TEST_REPO"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := gopt.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if opts.Repo != "fromenv" {
+		t.Fatalf("expected Repo to be bound from the env tag, got %q", opts.Repo)
+	}
+}
+
+func TestNewFromStruct(t *testing.T) {
+	type sub1cmd1Options struct {
+		Sub1Cmd1Opt1 string `long:"sub1cmd1opt1"`
+	}
+
+	type cmd1Options struct {
+		RootOpt1 string           `long:"rootopt1" default:"hello"`
+		Sub1Cmd1 *sub1cmd1Options `command:"sub1cmd1"`
+	}
+
+	type rootOptions struct {
+		Repo string       `short:"r" long:"repo" description:"the repo to use" required:"true" env:"REPO"`
+		Cmd1 *cmd1Options `command:"cmd1"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	n, err := getNode(gopt.programTree, "cmd1", "sub1cmd1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := n.ChildOptions["sub1cmd1opt1"]; !ok {
+		t.Fatalf("sub1cmd1opt1 not registered: %s", n.Str())
+	}
+
+	if _, ok := gopt.programTree.ChildOptions["repo"]; !ok {
+		t.Fatalf("repo not registered")
+	}
+}
+
+func TestNewFromStructShortFlagBindsField(t *testing.T) {
+	type rootOptions struct {
+		Repo string `short:"r" long:"repo"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := gopt.Parse([]string{"-r", "myrepo"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if opts.Repo != "myrepo" {
+		t.Fatalf("expected Repo to be bound through the short flag, got %q", opts.Repo)
+	}
+}
+
+func TestNewFromStructDefaultTagBindsField(t *testing.T) {
+	type rootOptions struct {
+		RootOpt1 string `long:"rootopt1" default:"hello"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := gopt.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if opts.RootOpt1 != "hello" {
+		t.Fatalf("expected RootOpt1 to be bound from its default tag, got %q", opts.RootOpt1)
+	}
+}
+
+func TestNewFromStructDefaultTagDoesNotDoubleCountSliceField(t *testing.T) {
+	type rootOptions struct {
+		Tags []string `long:"tag" default:"defaultval"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := gopt.Parse([]string{"--tag", "real"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(opts.Tags) != 1 || opts.Tags[0] != "real" {
+		t.Fatalf("expected Tags to hold only the CLI occurrence [real], got %v", opts.Tags)
+	}
+}
+
+func TestNewFromStructRequiredOptionMissing(t *testing.T) {
+	type rootOptions struct {
+		Repo string `long:"repo" required:"true"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := gopt.Parse(nil); err == nil {
+		t.Fatalf("expected an error for a missing required option")
+	}
+}
+
+func TestNewFromStructRequiredOptionFromEnv(t *testing.T) {
+	t.Setenv("GETOPTIONS_TEST_REQUIRED_REPO", "fromenv")
+
+	type rootOptions struct {
+		Repo string `long:"repo" required:"true" env:"GETOPTIONS_TEST_REQUIRED_REPO"`
+	}
+
+	opts := rootOptions{}
+	gopt, err := NewFromStruct(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := gopt.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: a required option satisfied by its env tag must not fail Parse: %s", err)
+	}
+}
+
+func TestNewFromStructRequiresPointer(t *testing.T) {
+	type opts struct{}
+	_, err := NewFromStruct(opts{})
+	if err == nil {
+		t.Fatalf("expected error for non-pointer value")
+	}
+}
@@ -0,0 +1,152 @@
+package getoptions
+
+import (
+	"fmt"
+	"sort"
+)
+
+// maxSuggestionDistance bounds how different a candidate may be from the
+// unrecognized token and still be offered as a suggestion. 2 matches the
+// distance docopt/cobra-style "did you mean" features commonly use: close
+// enough to catch typos, far enough not to suggest unrelated names.
+const maxSuggestionDistance = 2
+
+// ErrorUnknownCommand is returned (wrapped) by parseCLIArgs when a token in
+// command position doesn't match any of ChildCommands at that node but is
+// within maxSuggestionDistance of one of them; a token farther from every
+// command name is kept as ChildText instead. Unwrap
+// it with errors.Is against ErrorUnknownCommand; call Suggestions for the
+// closest sibling command names, if any were found.
+type ErrorUnknownCommand struct {
+	Command     string
+	suggestions []string
+}
+
+func (e *ErrorUnknownCommand) Error() string {
+	return fmt.Sprintf("unknown command: %s", e.Command)
+}
+
+func (e *ErrorUnknownCommand) Is(target error) bool {
+	_, ok := target.(*ErrorUnknownCommand)
+	return ok
+}
+
+// Suggestions returns the sibling command names closest to the token that
+// failed to match, ordered nearest-first. It is empty when nothing was
+// within maxSuggestionDistance.
+func (e *ErrorUnknownCommand) Suggestions() []string {
+	return e.suggestions
+}
+
+// ErrorUnknownOption is the --flag counterpart of ErrorUnknownCommand: it is
+// returned (wrapped) when a long option name doesn't match anything in scope
+// at the current node, including inherited/persistent options.
+type ErrorUnknownOption struct {
+	Option      string
+	suggestions []string
+}
+
+func (e *ErrorUnknownOption) Error() string {
+	return fmt.Sprintf("unknown option: %s", e.Option)
+}
+
+func (e *ErrorUnknownOption) Is(target error) bool {
+	_, ok := target.(*ErrorUnknownOption)
+	return ok
+}
+
+// Suggestions returns the in-scope option long names closest to the token
+// that failed to match, ordered nearest-first. It is empty when nothing was
+// within maxSuggestionDistance.
+func (e *ErrorUnknownOption) Suggestions() []string {
+	return e.suggestions
+}
+
+// newErrorUnknownCommand builds an ErrorUnknownCommand for name, wrapping
+// cause (the lower-level parse error this replaces) and populating
+// Suggestions from candidates.
+func newErrorUnknownCommand(name string, candidates []string, cause error) error {
+	return fmt.Errorf("%w: %w", &ErrorUnknownCommand{Command: name, suggestions: suggest(name, candidates)}, cause)
+}
+
+// newErrorUnknownOption builds an ErrorUnknownOption for name, wrapping
+// cause (the lower-level parse error this replaces) and populating
+// Suggestions from candidates.
+func newErrorUnknownOption(name string, candidates []string, cause error) error {
+	return fmt.Errorf("%w: %w", &ErrorUnknownOption{Option: name, suggestions: suggest(name, candidates)}, cause)
+}
+
+// suggest returns the entries of candidates within maxSuggestionDistance of
+// name, nearest first, ties broken alphabetically so results are stable.
+func suggest(name string, candidates []string) []string {
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	for _, c := range candidates {
+		d := damerauLevenshtein(name, c)
+		if d <= maxSuggestionDistance {
+			matches = append(matches, scored{c, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+	out := make([]string, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, m.name)
+	}
+	return out
+}
+
+// damerauLevenshtein computes the optimal string alignment distance between
+// a and b: single-character insertions, deletions, substitutions, and
+// transpositions of adjacent characters all cost 1.
+func damerauLevenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	la, lb := len(ar), len(br)
+
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(
+				d[i-1][j]+1,      // deletion
+				d[i][j-1]+1,      // insertion
+				d[i-1][j-1]+cost, // substitution
+			)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				if t := d[i-2][j-2] + cost; t < d[i][j] {
+					d[i][j] = t
+				}
+			}
+		}
+	}
+	return d[la][lb]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
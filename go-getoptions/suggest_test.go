@@ -0,0 +1,119 @@
+package getoptions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDamerauLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"cmd1", "cmd1", 0},
+		{"cmd", "cmd1", 1},
+		{"cmd3", "cmd1", 1},
+		{"rootopt2", "rootopt1", 1},
+		{"ab", "ba", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, test := range tests {
+		if got := damerauLevenshtein(test.a, test.b); got != test.expected {
+			t.Errorf("damerauLevenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.expected)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		expected   []string
+	}{
+		{"cmd", []string{"cmd1", "cmd2"}, []string{"cmd1", "cmd2"}},
+		{"rootopt2", []string{"rootopt1", "unrelated"}, []string{"rootopt1"}},
+		{"completely-different", []string{"cmd1", "cmd2"}, []string{}},
+	}
+	for _, test := range tests {
+		got := suggest(test.name, test.candidates)
+		if len(got) == 0 {
+			got = []string{}
+		}
+		if !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("suggest(%q, %v) = %v, want %v", test.name, test.candidates, got, test.expected)
+		}
+	}
+}
+
+func TestErrorUnknownCommandSuggestions(t *testing.T) {
+	err := newErrorUnknownCommand("cmd3", []string{"cmd1", "cmd2"}, errors.New("not found"))
+
+	var unknownCmd *ErrorUnknownCommand
+	if !errors.As(err, &unknownCmd) {
+		t.Fatalf("expected errors.As to find *ErrorUnknownCommand in %v", err)
+	}
+	if !reflect.DeepEqual(unknownCmd.Suggestions(), []string{"cmd1", "cmd2"}) {
+		t.Errorf("expected suggestions [cmd1 cmd2], got %v", unknownCmd.Suggestions())
+	}
+}
+
+func TestErrorUnknownOptionSuggestions(t *testing.T) {
+	err := newErrorUnknownOption("rootopt2", []string{"rootopt1"}, errors.New("not found"))
+
+	var unknownOpt *ErrorUnknownOption
+	if !errors.As(err, &unknownOpt) {
+		t.Fatalf("expected errors.As to find *ErrorUnknownOption in %v", err)
+	}
+	if !reflect.DeepEqual(unknownOpt.Suggestions(), []string{"rootopt1"}) {
+		t.Errorf("expected suggestions [rootopt1], got %v", unknownOpt.Suggestions())
+	}
+}
+
+func TestParseSuggestsOnUnknownOption(t *testing.T) {
+	gopt := setupOpt()
+	_, err := gopt.Parse([]string{"--rootopt2"})
+
+	var unknownOpt *ErrorUnknownOption
+	if !errors.As(err, &unknownOpt) {
+		t.Fatalf("expected errors.As to find *ErrorUnknownOption in %v", err)
+	}
+	if !reflect.DeepEqual(unknownOpt.Suggestions(), []string{"rootopt1"}) {
+		t.Errorf("expected suggestions [rootopt1], got %v", unknownOpt.Suggestions())
+	}
+}
+
+func TestParseSuggestsOnUnknownCommand(t *testing.T) {
+	gopt := setupOpt()
+	_, err := gopt.Parse([]string{"cmd3"})
+
+	var unknownCmd *ErrorUnknownCommand
+	if !errors.As(err, &unknownCmd) {
+		t.Fatalf("expected errors.As to find *ErrorUnknownCommand in %v", err)
+	}
+	if !reflect.DeepEqual(unknownCmd.Suggestions(), []string{"cmd1", "cmd2"}) {
+		t.Errorf("expected suggestions [cmd1 cmd2], got %v", unknownCmd.Suggestions())
+	}
+}
+
+func TestParseCLIArgsCompletionModeSkipsUnknownOptionError(t *testing.T) {
+	gopt := setupOpt()
+	_, completions, err := parseCLIArgs(true, gopt.programTree, []string{"--root"}, Normal)
+	if err != nil {
+		t.Fatalf("unexpected error in completion mode: %s", err)
+	}
+	if !reflect.DeepEqual(*completions, []string{"--rootopt1 "}) {
+		t.Errorf("expected [\"--rootopt1 \"], got %v", *completions)
+	}
+}
+
+func TestParseLeavesFarOffCommandAsText(t *testing.T) {
+	gopt := setupOpt()
+	node, err := gopt.Parse([]string{"txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(node.ChildText) != 1 || *node.ChildText[0] != "txt" {
+		t.Fatalf("expected \"txt\" to be recorded as plain text, got %#v", node.ChildText)
+	}
+}
@@ -0,0 +1,361 @@
+package getoptions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// usageNodeKind enumerates the AST node shapes produced by parseUsageTokens.
+type usageNodeKind int
+
+const (
+	usageNodeSequence usageNodeKind = iota
+	usageNodeAlternatives
+	usageNodeOptional
+	usageNodeRequired
+	usageNodeRepeat
+	usageNodeCommand
+	usageNodeLongOption
+	usageNodeShortOption
+	usageNodeArgument
+)
+
+// usageNode is one node of the usage AST built from the tokens produced by
+// tokenizeUsage. Sequence and Alternatives carry their members in Children;
+// Optional/Required/Repeat wrap a single child; the remaining kinds are
+// leaves carrying Name.
+type usageNode struct {
+	kind     usageNodeKind
+	name     string
+	children []*usageNode
+}
+
+// parseUsageTokens builds a usageNode AST out of tokens, honoring `|`
+// alternation (lowest precedence), `[...]` optional groups, `(...)` required
+// groups, and a trailing `...` marking the previous element as repeatable.
+func parseUsageTokens(tokens []usageToken) (*usageNode, error) {
+	p := &usageTokenParser{tokens: tokens}
+	node, err := p.parseAlternatives()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("usage: unexpected trailing token %q", p.tokens[p.pos].text)
+	}
+	return node, nil
+}
+
+type usageTokenParser struct {
+	tokens []usageToken
+	pos    int
+}
+
+func (p *usageTokenParser) peek() (usageToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return usageToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *usageTokenParser) parseAlternatives() (*usageNode, error) {
+	first, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	alts := []*usageNode{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != usageTokenPipe {
+			break
+		}
+		p.pos++
+		next, err := p.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, next)
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return &usageNode{kind: usageNodeAlternatives, children: alts}, nil
+}
+
+func (p *usageTokenParser) parseSequence() (*usageNode, error) {
+	var children []*usageNode
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == usageTokenPipe || tok.kind == usageTokenCloseOptional || tok.kind == usageTokenCloseRequired {
+			break
+		}
+		node, err := p.parseElement()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 0 {
+		return nil, fmt.Errorf("usage: expected at least one element in sequence")
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &usageNode{kind: usageNodeSequence, children: children}, nil
+}
+
+func (p *usageTokenParser) parseElement() (*usageNode, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("usage: unexpected end of input")
+	}
+
+	var node *usageNode
+	switch tok.kind {
+	case usageTokenOpenOptional:
+		p.pos++
+		inner, err := p.parseAlternatives()
+		if err != nil {
+			return nil, err
+		}
+		closeTok, ok := p.peek()
+		if !ok || closeTok.kind != usageTokenCloseOptional {
+			return nil, fmt.Errorf("usage: expected closing ']'")
+		}
+		p.pos++
+		node = &usageNode{kind: usageNodeOptional, children: []*usageNode{inner}}
+	case usageTokenOpenRequired:
+		p.pos++
+		inner, err := p.parseAlternatives()
+		if err != nil {
+			return nil, err
+		}
+		closeTok, ok := p.peek()
+		if !ok || closeTok.kind != usageTokenCloseRequired {
+			return nil, fmt.Errorf("usage: expected closing ')'")
+		}
+		p.pos++
+		node = &usageNode{kind: usageNodeRequired, children: []*usageNode{inner}}
+	case usageTokenCommand:
+		p.pos++
+		node = &usageNode{kind: usageNodeCommand, name: tok.text}
+	case usageTokenLongOption:
+		p.pos++
+		node = &usageNode{kind: usageNodeLongOption, name: tok.text}
+	case usageTokenShortOption:
+		p.pos++
+		node = &usageNode{kind: usageNodeShortOption, name: tok.text}
+	case usageTokenArgument:
+		p.pos++
+		node = &usageNode{kind: usageNodeArgument, name: tok.text}
+	default:
+		return nil, fmt.Errorf("usage: unexpected token %v", tok)
+	}
+
+	if next, ok := p.peek(); ok && next.kind == usageTokenEllipsis {
+		p.pos++
+		node = &usageNode{kind: usageNodeRepeat, children: []*usageNode{node}}
+	}
+	return node, nil
+}
+
+// usageOptionMeta carries the description/default parsed out of the
+// `Options:` block below the usage line, keyed by long option name, the same
+// metadata NewOption/Description/opt.Save would otherwise be told about
+// one call at a time.
+type usageOptionMeta struct {
+	description string
+	defaultVal  string
+}
+
+// NewFromUsage parses a POSIX/docopt-style usage block into the same
+// programTree/ChildOptions structure NewOption/NewCommand build by hand.
+// usage is expected to start with the program's usage line (e.g.
+// "prog cmd1 [--rootopt1=<v>] sub1cmd1 [--sub1cmd1opt1=<v>] [<txt>...]")
+// optionally followed by a blank line and an "Options:" block that supplies
+// descriptions and defaults. Everything before "Options:" is read as one
+// line whose first word is the program name, so a second usage line is not
+// treated as an alternative form:
+//
+//	Options:
+//	  --rootopt1=<v>  Root option [default: hello]
+//
+// The resulting tree is walked by the same parseCLIArgs used everywhere
+// else, so repeat (`...`) and mutual exclusion (`a | b`) groups recorded in
+// the AST are enforced in a post-parse validation pass (see ValidateUsage)
+// rather than during tokenizing/tree-building. A bare `<name>...`, not
+// wrapped in `[...]`, requires at least one occurrence; `[<name>...]` allows
+// zero. Only positional argument repeats are counted this way; repeats of a
+// command or option are parsed but not yet enforced.
+func NewFromUsage(usage string) (*GetOpt, error) {
+	usageLine, optionsBlock := splitUsageSections(usage)
+	meta, err := parseOptionsBlock(optionsBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(usageLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("usage: empty usage line")
+	}
+	rest := strings.Join(fields[1:], " ")
+
+	tokens, err := tokenizeUsage(rest)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := parseUsageTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	gopt := New()
+	builder := &usageTreeBuilder{meta: meta}
+	if _, err := builder.apply(gopt, ast); err != nil {
+		return nil, err
+	}
+	gopt.usageValidation = builder.validations
+	return gopt, nil
+}
+
+// splitUsageSections separates the usage line(s) from a trailing
+// "Options:" block, so descriptions/defaults can be parsed independently of
+// the positional grammar.
+func splitUsageSections(usage string) (usageLine, optionsBlock string) {
+	idx := strings.Index(usage, "Options:")
+	if idx < 0 {
+		return strings.TrimSpace(usage), ""
+	}
+	return strings.TrimSpace(usage[:idx]), usage[idx+len("Options:"):]
+}
+
+// parseOptionsBlock reads lines like
+// "  --rootopt1=<v>  Root option [default: hello]" out of the Options:
+// section into a map keyed by long option name.
+func parseOptionsBlock(block string) (map[string]usageOptionMeta, error) {
+	meta := map[string]usageOptionMeta{}
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "-") {
+			continue
+		}
+		fields := strings.Fields(line)
+		name, _ := splitOptionName(strings.TrimLeft(fields[0], "-"))
+		description := strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+		def := ""
+		if i := strings.Index(description, "[default:"); i >= 0 {
+			end := strings.IndexByte(description[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("usage: unterminated [default: ...] in %q", line)
+			}
+			def = strings.TrimSpace(strings.TrimPrefix(description[i:i+end], "[default:"))
+			description = strings.TrimSpace(description[:i])
+		}
+		meta[name] = usageOptionMeta{description: description, defaultVal: def}
+	}
+	return meta, nil
+}
+
+// usageValidation records a repeat or mutual-exclusion constraint discovered
+// while walking the AST, to be checked against the parsed arg count/choice
+// after parseCLIArgs runs rather than while the tree is still being built.
+type usageValidation struct {
+	repeat string // argument/command name allowed to repeat, if any
+
+	// repeatIsArgument is true when the repeated element is a positional
+	// <name> placeholder, the only repeat shape ValidateUsage currently
+	// knows how to count occurrences of (against ChildText).
+	repeatIsArgument bool
+
+	// repeatRequired is true when the repeat was not reached through an
+	// Optional ([...]) ancestor, so zero occurrences must be rejected
+	// rather than treated as "0..N are all valid".
+	repeatRequired bool
+
+	// node is the programTree node current at the point the repeat or
+	// alternation was declared (before any command further along the usage
+	// line was entered). For a repeat, a value satisfying it may land on
+	// node itself or on a descendant parseCLIArgs later resolved into (see
+	// subtreeHasChildText in usage_validate.go). For an alternation, it is
+	// the node the alternative options were registered on and the node
+	// alternative commands are children of (see validateAlternation).
+	node *programTree
+
+	alternation []string // sibling names that are mutually exclusive
+
+	// alternationRequired is true when the alternation was not reached
+	// through an Optional ([...]) ancestor, so exactly one of alternation
+	// must be used rather than "at most one".
+	alternationRequired bool
+}
+
+// usageTreeBuilder walks a usage AST, registering commands/options/arguments
+// on the programTree being built and collecting usageValidations for the
+// post-parse pass.
+type usageTreeBuilder struct {
+	meta        map[string]usageOptionMeta
+	validations []usageValidation
+}
+
+// apply registers node (and, for a sequence, each of its children in order)
+// starting at cur, returning the node subsequent siblings should register
+// against - entering a command shifts that context one level down the tree,
+// mirroring how a real CLI invocation descends into the command once it
+// sees the command's name on the command line.
+func (b *usageTreeBuilder) apply(cur commandAdder, node *usageNode) (commandAdder, error) {
+	return b.applyNode(cur, node, false)
+}
+
+// applyNode is apply's worker, threading optional through the recursion so a
+// usageNodeRepeat reached underneath a usageNodeOptional ([...]) can record
+// that zero occurrences are allowed, as opposed to a bare repeat at the top
+// level of a sequence, which requires at least one.
+func (b *usageTreeBuilder) applyNode(cur commandAdder, node *usageNode, optional bool) (commandAdder, error) {
+	switch node.kind {
+	case usageNodeSequence:
+		for _, c := range node.children {
+			next, err := b.applyNode(cur, c, optional)
+			if err != nil {
+				return nil, err
+			}
+			cur = next
+		}
+		return cur, nil
+	case usageNodeOptional:
+		return b.applyNode(cur, node.children[0], true)
+	case usageNodeRequired:
+		return b.applyNode(cur, node.children[0], optional)
+	case usageNodeRepeat:
+		inner := node.children[0]
+		b.validations = append(b.validations, usageValidation{
+			repeat:           inner.name,
+			repeatIsArgument: inner.kind == usageNodeArgument,
+			repeatRequired:   !optional,
+			node:             cur.tree(),
+		})
+		return b.applyNode(cur, inner, optional)
+	case usageNodeAlternatives:
+		names := make([]string, 0, len(node.children))
+		for _, c := range node.children {
+			names = append(names, c.name)
+			if _, err := b.applyNode(cur, c, optional); err != nil {
+				return nil, err
+			}
+		}
+		b.validations = append(b.validations, usageValidation{alternation: names, node: cur.tree(), alternationRequired: !optional})
+		return cur, nil
+	case usageNodeCommand:
+		m := b.meta[node.name]
+		return cur.NewCommand(node.name, m.description), nil
+	case usageNodeLongOption, usageNodeShortOption:
+		m := b.meta[node.name]
+		opt := cur.NewOption(node.name, m.defaultVal)
+		opt.Description(m.description)
+		return cur, nil
+	case usageNodeArgument:
+		// Arguments are positional text slots; the tree itself has no
+		// dedicated registration step for them, they are simply validated
+		// against ChildText after parseCLIArgs (see usageValidation).
+		return cur, nil
+	}
+	return cur, nil
+}
@@ -0,0 +1,134 @@
+package getoptions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// usageTokenKind enumerates the token classes produced by tokenizeUsage.
+type usageTokenKind int
+
+const (
+	usageTokenCommand usageTokenKind = iota
+	usageTokenLongOption
+	usageTokenShortOption
+	usageTokenArgument
+	usageTokenOpenOptional  // [
+	usageTokenCloseOptional // ]
+	usageTokenOpenRequired  // (
+	usageTokenCloseRequired // )
+	usageTokenPipe          // |
+	usageTokenEllipsis      // ...
+)
+
+// usageToken is one lexical element of a docopt-style usage line, e.g.
+// `cmd1`, `--rootopt1=<v>`, `<txt>`, `[`, `...`.
+type usageToken struct {
+	kind usageTokenKind
+	text string // option name or argument/command name, without decoration
+}
+
+// tokenizeUsage splits a single usage line (the part after the program name)
+// into usageTokens. It understands the subset of docopt syntax described by
+// the chunk0-4 request: commands, long/short options (optionally with
+// `=<v>`), `<argument>` placeholders, `[optional]`, `(required)`, `a | b`
+// alternation, and trailing `...` repetition.
+func tokenizeUsage(line string) ([]usageToken, error) {
+	var tokens []usageToken
+	fields := splitUsageFields(line)
+	for _, f := range fields {
+		for len(f) > 0 {
+			switch {
+			case f == "...":
+				tokens = append(tokens, usageToken{kind: usageTokenEllipsis})
+				f = ""
+			case f[0] == '[':
+				tokens = append(tokens, usageToken{kind: usageTokenOpenOptional})
+				f = f[1:]
+			case f[0] == ']':
+				tokens = append(tokens, usageToken{kind: usageTokenCloseOptional})
+				f = f[1:]
+			case f[0] == '(':
+				tokens = append(tokens, usageToken{kind: usageTokenOpenRequired})
+				f = f[1:]
+			case f[0] == ')':
+				tokens = append(tokens, usageToken{kind: usageTokenCloseRequired})
+				f = f[1:]
+			case f[0] == '|':
+				tokens = append(tokens, usageToken{kind: usageTokenPipe})
+				f = f[1:]
+			case f[0] == '=':
+				// Separates an option from its `<v>` placeholder; the
+				// placeholder itself is tokenized as a plain argument on
+				// the next iteration.
+				f = f[1:]
+			case strings.HasPrefix(f, "--"):
+				name, rest := splitOptionName(f[2:])
+				tokens = append(tokens, usageToken{kind: usageTokenLongOption, text: name})
+				f = rest
+			case f[0] == '-' && len(f) > 1:
+				name, rest := splitOptionName(f[1:])
+				tokens = append(tokens, usageToken{kind: usageTokenShortOption, text: name})
+				f = rest
+			case f[0] == '<':
+				end := strings.IndexByte(f, '>')
+				if end < 0 {
+					return nil, fmt.Errorf("usage: unterminated argument placeholder in %q", f)
+				}
+				tokens = append(tokens, usageToken{kind: usageTokenArgument, text: f[1:end]})
+				f = f[end+1:]
+			default:
+				name, rest := splitOptionName(f)
+				tokens = append(tokens, usageToken{kind: usageTokenCommand, text: name})
+				f = rest
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// splitUsageFields tokenizes on whitespace but keeps the docopt punctuation
+// characters ([]()|) as their own fields even when not whitespace-separated,
+// e.g. "[--rootopt1=<v>]" becomes "[", "--rootopt1=<v>", "]".
+func splitUsageFields(line string) []string {
+	var fields []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			fields = append(fields, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range line {
+		switch r {
+		case ' ', '\t':
+			flush()
+		case '[', ']', '(', ')', '|':
+			flush()
+			fields = append(fields, string(r))
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return fields
+}
+
+// splitOptionName stops at the first '=', ']', ')', '|', or a trailing
+// '...', returning the bare name and any trailing text still to be lexed
+// (e.g. the `<v>` in `--rootopt1=<v>`, or a `]`/`...` glued directly onto the
+// token with no separating space, as in `cmd1...` or `--opt...`).
+func splitOptionName(s string) (name, rest string) {
+	for i, r := range s {
+		if r == '=' {
+			return s[:i], s[i:]
+		}
+		if r == ']' || r == ')' || r == '|' {
+			return s[:i], s[i:]
+		}
+		if strings.HasPrefix(s[i:], "...") {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
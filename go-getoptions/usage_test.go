@@ -0,0 +1,249 @@
+package getoptions
+
+import "testing"
+
+func TestNewFromUsage(t *testing.T) {
+	usage := `prog cmd1 [--rootopt1=<v>] sub1cmd1 [--sub1cmd1opt1=<v>] [<txt>...]
+
+Options:
+  --rootopt1=<v>       Root option [default: hello]
+  --sub1cmd1opt1=<v>   Sub1cmd1 option
+`
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	n, err := getNode(gopt.programTree, "cmd1", "sub1cmd1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	opt, ok := n.ChildOptions["sub1cmd1opt1"]
+	if !ok {
+		t.Fatalf("sub1cmd1opt1 not registered: %s", n.Str())
+	}
+	_ = opt
+}
+
+func TestParseEnforcesUsageAlternation(t *testing.T) {
+	usage := `prog (--a | --b)`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse([]string{"--a", "x"}); err != nil {
+		t.Errorf("unexpected error for a single alternative: %s", err)
+	}
+
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse([]string{"--a", "x", "--b", "y"}); err == nil {
+		t.Fatalf("expected Parse to reject both mutually exclusive alternatives being used")
+	}
+}
+
+func TestParseEnforcesUsageAlternationRequiresOne(t *testing.T) {
+	usage := `prog (--a | --b)`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse(nil); err == nil {
+		t.Fatalf("expected Parse to reject a required alternation with neither alternative used")
+	}
+
+	usage = `prog (cmd1 | cmd2)`
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse(nil); err == nil {
+		t.Fatalf("expected Parse to reject a required command alternation with neither command used")
+	}
+
+	usage = `prog [--a | --b]`
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse(nil); err != nil {
+		t.Errorf("unexpected error for an optional alternation with neither alternative used: %s", err)
+	}
+}
+
+func TestParseEnforcesUsageAlternationAcrossCommandLevels(t *testing.T) {
+	usage := `prog (--a | --b) cmd1`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	gopt.programTree.ChildOptions["a"].NoArg()
+	gopt.programTree.ChildOptions["b"].NoArg()
+	if _, err := gopt.Parse([]string{"--a", "cmd1"}); err != nil {
+		t.Errorf("unexpected error for a single alternative: %s", err)
+	}
+
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	gopt.programTree.ChildOptions["a"].NoArg()
+	gopt.programTree.ChildOptions["b"].NoArg()
+	if _, err := gopt.Parse([]string{"--a", "--b", "cmd1"}); err == nil {
+		t.Fatalf("expected Parse to reject both mutually exclusive alternatives being used, even once cmd1 is reached")
+	}
+}
+
+func TestNewFromUsageRoundTripsParseCLIArgsBehavior(t *testing.T) {
+	usage := `prog cmd1 [--cmd1opt1=<v>] sub1cmd1 [--sub1cmd1opt1=<v>] [<txt>...]`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	node, err := gopt.Parse([]string{"cmd1", "sub1cmd1", "txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.Name != "sub1cmd1" {
+		t.Fatalf("expected to land on sub1cmd1, got %s", node.Name)
+	}
+	if len(node.ChildText) != 1 || *node.ChildText[0] != "txt" {
+		t.Fatalf("expected ChildText [txt], got %#v", node.ChildText)
+	}
+
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	node, err = gopt.Parse([]string{"cmd1", "sub1cmd1", "--sub1cmd1opt1", "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	opt, ok := node.ChildOptions["sub1cmd1opt1"]
+	if !ok || !opt.Called {
+		t.Fatalf("expected sub1cmd1opt1 to be Called, got %#v", opt)
+	}
+}
+
+func TestParseEnforcesUsageRepeat(t *testing.T) {
+	usage := `prog <txt>...`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse(nil); err == nil {
+		t.Fatalf("expected an error when a bare <txt>... has zero occurrences")
+	}
+
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse([]string{"hello"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestParseEnforcesUsageRepeatAcrossCommandLevels(t *testing.T) {
+	usage := `prog <a>... sub1cmd1 [<txt>...]`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse([]string{"sub1cmd1", "y"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	gopt, err = NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse([]string{"sub1cmd1"}); err == nil {
+		t.Fatalf("expected an error: <a>... has zero occurrences anywhere in the tree")
+	}
+}
+
+func TestParseAllowsZeroOccurrencesForOptionalRepeat(t *testing.T) {
+	usage := `prog [<txt>...]`
+
+	gopt, err := NewFromUsage(usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := gopt.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: a repeat wrapped in [...] must allow zero occurrences: %s", err)
+	}
+}
+
+func TestTokenizeUsage(t *testing.T) {
+	tokens, err := tokenizeUsage("cmd1 [--rootopt1=<v>] sub1cmd1 [<txt>...]")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []usageTokenKind{
+		usageTokenCommand,
+		usageTokenOpenOptional, usageTokenLongOption, usageTokenArgument, usageTokenCloseOptional,
+		usageTokenCommand,
+		usageTokenOpenOptional, usageTokenArgument, usageTokenEllipsis, usageTokenCloseOptional,
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("expected %d tokens, got %d: %#v", len(want), len(tokens), tokens)
+	}
+	for i, k := range want {
+		if tokens[i].kind != k {
+			t.Errorf("token %d: expected kind %d, got %d (%#v)", i, k, tokens[i].kind, tokens[i])
+		}
+	}
+}
+
+func TestTokenizeUsageEllipsisGluedToToken(t *testing.T) {
+	tokens, err := tokenizeUsage("cmd1... --opt...")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []usageTokenKind{
+		usageTokenCommand, usageTokenEllipsis,
+		usageTokenLongOption, usageTokenEllipsis,
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("expected %d tokens, got %d: %#v", len(want), len(tokens), tokens)
+	}
+	for i, k := range want {
+		if tokens[i].kind != k {
+			t.Errorf("token %d: expected kind %d, got %d (%#v)", i, k, tokens[i].kind, tokens[i])
+		}
+	}
+	if tokens[0].text != "cmd1" {
+		t.Errorf("expected command name %q, got %q", "cmd1", tokens[0].text)
+	}
+	if tokens[2].text != "opt" {
+		t.Errorf("expected option name %q, got %q", "opt", tokens[2].text)
+	}
+}
+
+func TestParseOptionsBlock(t *testing.T) {
+	meta, err := parseOptionsBlock("  --rootopt1=<v>  Root option [default: hello]\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	m, ok := meta["rootopt1"]
+	if !ok {
+		t.Fatalf("rootopt1 not parsed")
+	}
+	if m.defaultVal != "hello" {
+		t.Errorf("expected default 'hello', got %q", m.defaultVal)
+	}
+	if m.description != "Root option" {
+		t.Errorf("expected description 'Root option', got %q", m.description)
+	}
+}
@@ -0,0 +1,103 @@
+package getoptions
+
+import "fmt"
+
+// ValidateUsage re-checks a tree built by NewFromUsage against the repeat
+// (`...`) and mutual-exclusion (`a | b`) constraints recorded while walking
+// the usage AST. It is meant to run after parseCLIArgs, since those
+// constraints are about how many times/which alternative was actually used,
+// not about the shape of the tree itself.
+func (gopt *GetOpt) ValidateUsage(n *programTree) error {
+	for _, v := range gopt.usageValidation {
+		if v.repeat != "" {
+			if err := validateRepeat(v); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(v.alternation) > 0 {
+			if err := validateAlternation(n, v.node, v.alternation, v.alternationRequired); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateRepeat enforces a repeat (`...`) constraint: a bare repeat, not
+// wrapped in `[...]`, requires at least one occurrence. A repeat wrapped in
+// `[...]` (v.repeatRequired false) allows any count from 0..N, so there is
+// nothing to check. Only argument-kind repeats (`<name>...`) are counted.
+//
+// The occurrence check walks v.node's subtree rather than just v.node
+// itself: v.node is the node current in the usage line at the point the
+// repeat was declared, but a value satisfying it lands on whichever
+// descendant parseCLIArgs actually finishes on (see parseCLIArgs's
+// ChildText handling), since a repeat declared before a command in the
+// usage line doesn't stop parseCLIArgs from descending into that command
+// first.
+func validateRepeat(v usageValidation) error {
+	if !v.repeatRequired || !v.repeatIsArgument {
+		return nil
+	}
+	if !subtreeHasChildText(v.node) {
+		return fmt.Errorf("usage: %s requires at least one value, got none", v.repeat)
+	}
+	return nil
+}
+
+// subtreeHasChildText reports whether n or any node reachable from n
+// through ChildCommands has at least one ChildText value.
+func subtreeHasChildText(n *programTree) bool {
+	if len(n.ChildText) > 0 {
+		return true
+	}
+	for _, c := range n.ChildCommands {
+		if subtreeHasChildText(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAlternation ensures exactly one of names was actually supplied
+// when required is true (a bare `(a | b)`, not wrapped in `[...]`), matching
+// the docopt semantics of "a | b" meaning exactly one of the two branches,
+// not both and not neither. An alternation reached through an Optional
+// ([...]) ancestor (required false) only rejects more than one, the same as
+// before. decl is the node the alternatives were registered against at
+// usage-build time (options directly on decl.ChildOptions, commands as
+// decl's immediate ChildCommands) - not n, the node parseCLIArgs finally
+// landed on, which is almost always a descendant of decl once a command
+// follows the alternation in the usage line.
+func validateAlternation(n, decl *programTree, names []string, required bool) error {
+	var used []string
+	for _, name := range names {
+		if opt, ok := decl.ChildOptions[name]; ok && opt.Called {
+			used = append(used, name)
+			continue
+		}
+		if commandWasEntered(n, decl, name) {
+			used = append(used, name)
+		}
+	}
+	if len(used) > 1 {
+		return fmt.Errorf("usage: %v are mutually exclusive, got %v", names, used)
+	}
+	if required && len(used) == 0 {
+		return fmt.Errorf("usage: exactly one of %v is required, got none", names)
+	}
+	return nil
+}
+
+// commandWasEntered reports whether name is decl's immediate child command
+// and parseCLIArgs actually descended into it on its way to n, by walking
+// n's Parent chain looking for that exact (decl, name) link.
+func commandWasEntered(n, decl *programTree, name string) bool {
+	for cur := n; cur != nil && cur != decl; cur = cur.Parent {
+		if cur.Name == name && cur.Parent == decl {
+			return true
+		}
+	}
+	return false
+}